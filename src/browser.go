@@ -17,9 +17,14 @@ func openBrowser() {
 }
 
 func runCommand(cmd string, args ...string) {
-	args = append(args, "http://localhost:"+getPortNumber("http")+"/mystream/publish")
+	args = append(args, publishUrl())
 	err := exec.Command(cmd, args...).Start()
 	if err != nil {
 		panic(err)
 	}
 }
+
+// publishUrl returns the MediaMTX page for publishing the camera
+func publishUrl() string {
+	return "http://localhost:" + getPortNumber("http") + "/mystream/publish"
+}
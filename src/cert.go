@@ -2,21 +2,39 @@
 package main
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
+	"net"
 	"os"
 	"path/filepath"
 	"time"
 )
 
-func generateCert() {
-	// Generate RSA 2048-bit private key
-	const rsaBits = 2048
-	priv, err := rsa.GenerateKey(rand.Reader, rsaBits)
+// generateCert writes a self-signed certificate to dir using an "rsa" or "ecdsa" key
+func generateCert(dir string, algo string) {
+	// Generate private key
+	var priv crypto.Signer
+	var err error
+	switch algo {
+	case "rsa":
+		// RSA 2048-bit
+		const rsaBits = 2048
+		priv, err = rsa.GenerateKey(rand.Reader, rsaBits)
+	case "ecdsa":
+		// ECDSA P-256
+		priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		log.Fatalf("Unsupported certificate algorithm: %s", algo)
+	}
 	if err != nil {
 		log.Fatalf("Failed to generate private key: %v", err)
 	}
@@ -27,7 +45,10 @@ func generateCert() {
 	// KeyUsage
 	// Only RSA subject keys should have the KeyEncipherment KeyUsage bits set.
 	// In the context of TLS this KeyUsage is particular to RSA key exchange and authentication.
-	keyUsage := x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	keyUsage := x509.KeyUsageDigitalSignature
+	if _, isRSA := priv.(*rsa.PrivateKey); isRSA {
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
 
 	// Serial number
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
@@ -36,6 +57,16 @@ func generateCert() {
 		log.Fatalf("Failed to generate serial number: %v", err)
 	}
 
+	// IP SANs
+	// Include the private LAN IPs so their printed https://<ip> URLs pass the name check.
+	// Public and temporary IPv6 addresses change too often; their URLs are marked as not covered.
+	ipAddresses := []net.IP{net.ParseIP("127.0.0.1")}
+	if ips, err := LocalPrivateIPs(); err == nil {
+		ipAddresses = append(ipAddresses, ips...)
+	} else {
+		log.Printf("Failed to detect local IP address: %v", err)
+	}
+
 	// Certificate template
 	template := &x509.Certificate{
 		SerialNumber: serialNumber,
@@ -44,6 +75,7 @@ func generateCert() {
 		KeyUsage:     keyUsage,
 		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		DNSNames:     []string{"localhost"},
+		IPAddresses:  ipAddresses,
 	}
 
 	// Create certificate
@@ -53,7 +85,6 @@ func generateCert() {
 	}
 
 	// Create cert temp directory
-	dir := ".certs"
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		os.Mkdir(dir, 0755)
 	} else if err != nil {
@@ -92,3 +123,60 @@ func generateCert() {
 
 	setHiddenAttribute(dir)
 }
+
+func loadCert(certPath string) (*x509.Certificate, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("no certificate found in " + certPath)
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certRenewalReason returns why the certificate should be regenerated, or "" if it is still usable
+func certRenewalReason(certPath string) string {
+	// Renew certificates this close to expiry
+	const renewBefore = 30 * 24 * time.Hour
+
+	cert, err := loadCert(certPath)
+	if err != nil {
+		return fmt.Sprintf("failed to load certificate: %v", err)
+	}
+
+	// Validity period
+	now := time.Now()
+	if now.Before(cert.NotBefore) {
+		return "certificate is not valid until " + cert.NotBefore.Format(time.RFC3339)
+	}
+	if now.After(cert.NotAfter) {
+		return "certificate expired at " + cert.NotAfter.Format(time.RFC3339)
+	}
+	if now.Add(renewBefore).After(cert.NotAfter) {
+		return "certificate expires at " + cert.NotAfter.Format(time.RFC3339)
+	}
+
+	// Private LAN IPs
+	if ips, err := LocalPrivateIPs(); err == nil {
+		for _, ip := range ips {
+			if !certHasIP(cert, ip) {
+				return "certificate does not include " + ip.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+func certHasIP(cert *x509.Certificate, ip net.IP) bool {
+	for _, certIP := range cert.IPAddresses {
+		if certIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGenerateCertIPs(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), ".certs")
+	generateCert(dir, "ecdsa")
+
+	cert, err := loadCert(filepath.Join(dir, "cert.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []net.IP{net.ParseIP("127.0.0.1")}
+	if ips, err := LocalPrivateIPs(); err == nil {
+		want = append(want, ips...)
+	} else {
+		t.Logf("no LAN IP to check: %v", err)
+	}
+	for _, ip := range want {
+		if !certHasIP(cert, ip) {
+			t.Errorf("certificate IPs %v do not include %v", cert.IPAddresses, ip)
+		}
+	}
+}
+
+func TestGenerateCertAlgo(t *testing.T) {
+	tests := []struct {
+		algo                string
+		wantKeyEncipherment bool
+	}{
+		{"ecdsa", false},
+		{"rsa", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algo, func(t *testing.T) {
+			dir := t.TempDir()
+			generateCert(dir, tt.algo)
+
+			pair, err := tls.LoadX509KeyPair(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch pair.PrivateKey.(type) {
+			case *ecdsa.PrivateKey:
+				if tt.algo != "ecdsa" {
+					t.Errorf("got an ECDSA key for %s", tt.algo)
+				}
+			case *rsa.PrivateKey:
+				if tt.algo != "rsa" {
+					t.Errorf("got an RSA key for %s", tt.algo)
+				}
+			default:
+				t.Errorf("unexpected key type %T", pair.PrivateKey)
+			}
+
+			cert, err := x509.ParseCertificate(pair.Certificate[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cert.KeyUsage&x509.KeyUsageKeyEncipherment != 0; got != tt.wantKeyEncipherment {
+				t.Errorf("KeyEncipherment = %v, want %v", got, tt.wantKeyEncipherment)
+			}
+			if cert.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+				t.Error("DigitalSignature not set")
+			}
+		})
+	}
+}
+
+func TestCertRenewalReason(t *testing.T) {
+	const day = 24 * time.Hour
+	now := time.Now()
+
+	lanIPs, err := LocalPrivateIPs()
+	if err != nil {
+		t.Logf("no LAN IP to check: %v", err)
+	}
+	allIPs := append([]net.IP{net.ParseIP("127.0.0.1")}, lanIPs...)
+
+	tests := []struct {
+		name      string
+		notBefore time.Time
+		notAfter  time.Time
+		ips       []net.IP
+		want      bool
+	}{
+		{"valid", now.Add(-day), now.Add(365 * day), allIPs, false},
+		{"expired", now.Add(-365 * day), now.Add(-day), allIPs, true},
+		{"nearly expired", now.Add(-365 * day), now.Add(10 * day), allIPs, true},
+		{"not yet valid", now.Add(day), now.Add(365 * day), allIPs, true},
+		{"missing LAN IP", now.Add(-day), now.Add(365 * day), []net.IP{net.ParseIP("127.0.0.1")}, len(lanIPs) > 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certPath := filepath.Join(t.TempDir(), "cert.pem")
+			writeTestCert(t, certPath, tt.notBefore, tt.notAfter, tt.ips)
+
+			reason := certRenewalReason(certPath)
+			if got := reason != ""; got != tt.want {
+				t.Errorf("certRenewalReason() = %q, want renewal %v", reason, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if reason := certRenewalReason(filepath.Join(t.TempDir(), "cert.pem")); reason == "" {
+			t.Error("certRenewalReason() = \"\", want a reason")
+		}
+	})
+}
+
+// writeTestCert writes a self-signed certificate with the given validity and IPs to certPath
+func writeTestCert(t *testing.T, certPath string, notBefore time.Time, notAfter time.Time, ips []net.IP) {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		IPAddresses:  ips,
+	}
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds the launcher settings.
+// Every flag can also be set by a CAMCAST_* environment variable,
+// e.g. -http-port by CAMCAST_HTTP_PORT. Flags take precedence.
+type Config struct {
+	CertAlgo        string
+	MediaMTXVersion string
+	RTSPS           bool
+	RTSPSOnly       bool
+	RTSPSCert       string
+	RTSPSKey        string
+	HTTPPort        int
+	HTTPSPort       int
+	NoBrowser       bool
+	TLSCert         string
+	TLSKey          string
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	TURNURLs        listFlag
+	TURNSecret      string
+	TURNTTL         time.Duration
+	AllowedOrigins  listFlag
+	PrintConfig     bool
+}
+
+// Flags whose values are redacted by -print-config
+var secretFlags = map[string]bool{
+	"turn-secret": true,
+}
+
+// listFlag is a comma-separated list flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Get() any {
+	return append([]string{}, *l...)
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = nil
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+var config Config
+
+// loadConfig fills config from the command line and the environment
+func loadConfig() error {
+	flag.StringVar(&config.CertAlgo, "cert-algo", "ecdsa", "key algorithm for the self-signed certificate (rsa or ecdsa)")
+	flag.StringVar(&config.MediaMTXVersion, "mediamtx-version", "", "MediaMTX release to use, e.g. v1.9.0 (default latest)")
+	flag.BoolVar(&config.RTSPS, "rtsps", false, "also serve RTSP over TLS (rtsps://)")
+	flag.BoolVar(&config.RTSPSOnly, "rtsps-only", false, "serve RTSP over TLS only, disabling plain RTSP")
+	flag.StringVar(&config.RTSPSCert, "rtsps-cert", "", "certificate for RTSPS (default the HTTPS certificate)")
+	flag.StringVar(&config.RTSPSKey, "rtsps-key", "", "private key for RTSPS (default the HTTPS key)")
+	flag.IntVar(&config.HTTPPort, "http-port", 8889, "port of the MediaMTX WebRTC server, listening on localhost only")
+	flag.IntVar(&config.HTTPSPort, "https-port", 8443, "port of the HTTPS reverse proxy")
+	flag.BoolVar(&config.NoBrowser, "no-browser", false, "don't open the publish page in a browser")
+	flag.StringVar(&config.TLSCert, "tls-cert", "", "certificate for the HTTPS server (default a generated self-signed certificate)")
+	flag.StringVar(&config.TLSKey, "tls-key", "", "private key for the HTTPS server (default a generated self-signed key)")
+	flag.DurationVar(&config.ReadTimeout, "read-timeout", 0, "MediaMTX read timeout; RTSP sessions idle this long are closed (default MediaMTX's)")
+	flag.DurationVar(&config.WriteTimeout, "write-timeout", 0, "MediaMTX write timeout (default MediaMTX's)")
+	flag.Var(&config.TURNURLs, "turn-urls", "comma-separated TURN server URLs offered by MediaMTX and /api/turn, e.g. turn:turn.example.com:3478")
+	flag.StringVar(&config.TURNSecret, "turn-secret", "", "TURN shared secret (coturn static-auth-secret); passed to MediaMTX and enables /api/turn. Prefer CAMCAST_TURN_SECRET to keep it out of the process list")
+	flag.DurationVar(&config.TURNTTL, "turn-ttl", 24*time.Hour, "lifetime of credentials from /api/turn")
+	flag.BoolVar(&config.PrintConfig, "print-config", false, "print the effective configuration as JSON and exit")
+	flag.Var(&config.AllowedOrigins, "allowed-origins", "comma-separated origins allowed to make cross-origin requests, e.g. https://example.com, or * for any (default same-origin only)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output(), "\nEvery flag can also be set by an environment variable, e.g. -http-port by CAMCAST_HTTP_PORT.")
+	}
+	flag.Parse()
+
+	// Flags given on the command line
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	// Environment variables for the rest
+	var err error
+	flag.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil {
+			return
+		}
+		name := envName(f.Name)
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := f.Value.Set(value); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %v", value, name, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return config.validate()
+}
+
+// printConfig writes the effective flag values as JSON, with secrets redacted
+func printConfig(w io.Writer) error {
+	values := make(map[string]any)
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name == "print-config" {
+			return
+		}
+
+		var value any = f.Value.String()
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		}
+		if d, ok := value.(time.Duration); ok {
+			value = d.String()
+		}
+		if secretFlags[f.Name] && f.Value.String() != "" {
+			value = "REDACTED"
+		}
+
+		values[f.Name] = value
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(values)
+}
+
+// envName returns the environment variable for a flag, e.g. CAMCAST_HTTP_PORT for http-port
+func envName(flagName string) string {
+	return "CAMCAST_" + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+func (c *Config) validate() error {
+	if c.CertAlgo != "rsa" && c.CertAlgo != "ecdsa" {
+		return fmt.Errorf("-cert-algo must be rsa or ecdsa, got %q", c.CertAlgo)
+	}
+
+	for _, port := range []struct {
+		name  string
+		value int
+	}{
+		{"-http-port", c.HTTPPort},
+		{"-https-port", c.HTTPSPort},
+	} {
+		if port.value < 1 || port.value > 65535 {
+			return fmt.Errorf("%s must be between 1 and 65535, got %d", port.name, port.value)
+		}
+	}
+	if c.HTTPPort == c.HTTPSPort {
+		return fmt.Errorf("-http-port and -https-port must differ, both are %d", c.HTTPPort)
+	}
+
+	if c.ReadTimeout < 0 || c.WriteTimeout < 0 {
+		return fmt.Errorf("-read-timeout and -write-timeout must not be negative")
+	}
+
+	if (c.TURNSecret == "") != (len(c.TURNURLs) == 0) {
+		return fmt.Errorf("-turn-urls and -turn-secret must be set together")
+	}
+	if c.TURNTTL <= 0 {
+		return fmt.Errorf("-turn-ttl must be positive")
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+	if (c.RTSPSCert == "") != (c.RTSPSKey == "") {
+		return fmt.Errorf("-rtsps-cert and -rtsps-key must be set together")
+	}
+
+	return nil
+}
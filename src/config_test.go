@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"defaults", func(c *Config) {}, false},
+		{"custom ports", func(c *Config) { c.HTTPPort, c.HTTPSPort = 9100, 9443 }, false},
+		{"http port zero", func(c *Config) { c.HTTPPort = 0 }, true},
+		{"https port too large", func(c *Config) { c.HTTPSPort = 65536 }, true},
+		{"negative port", func(c *Config) { c.HTTPPort = -1 }, true},
+		{"equal ports", func(c *Config) { c.HTTPPort, c.HTTPSPort = 9000, 9000 }, true},
+		{"turn", func(c *Config) { c.TURNURLs, c.TURNSecret = listFlag{"turn:turn.example.com:3478"}, "secret" }, false},
+		{"turn urls without secret", func(c *Config) { c.TURNURLs = listFlag{"turn:turn.example.com:3478"} }, true},
+		{"turn ttl zero", func(c *Config) { c.TURNTTL = 0 }, true},
+		{"tls cert and key", func(c *Config) { c.TLSCert, c.TLSKey = "cert.pem", "key.pem" }, false},
+		{"tls cert without key", func(c *Config) { c.TLSCert = "cert.pem" }, true},
+		{"tls key without cert", func(c *Config) { c.TLSKey = "key.pem" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{CertAlgo: "ecdsa", HTTPPort: 8889, HTTPSPort: 8443, TURNTTL: 24 * time.Hour}
+			tt.modify(&c)
+
+			if err := c.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// allowedOrigin reports whether origin is listed in allowed.
+// Same-origin requests don't send an Origin needing this check.
+func allowedOrigin(origin string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(a, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaMTXAllowOrigin returns the origin MediaMTX allows on local requests that skip the proxy.
+// MediaMTX takes a single origin, so without exactly one allowed origin only the proxy's own is allowed.
+func mediaMTXAllowOrigin(allowed []string, httpsPort int) string {
+	if len(allowed) == 1 {
+		return allowed[0]
+	}
+	return "https://localhost:" + strconv.Itoa(httpsPort)
+}
+
+// setCORSHeaders sets Access-Control-Allow-Origin for allowed origins and removes it otherwise.
+// Credentials are only allowed for origins listed by name, never for "*".
+func setCORSHeaders(header http.Header, origin string, allowed []string) {
+	if slices.Contains(allowed, "*") {
+		header.Set("Access-Control-Allow-Origin", "*")
+		header.Del("Access-Control-Allow-Credentials")
+		return
+	}
+
+	header.Add("Vary", "Origin")
+	if origin != "" && allowedOrigin(origin, allowed) {
+		header.Set("Access-Control-Allow-Origin", origin)
+		return
+	}
+	header.Del("Access-Control-Allow-Origin")
+	header.Del("Access-Control-Allow-Credentials")
+}
@@ -2,7 +2,12 @@
 
 package main
 
-import ()
+import (
+	"os/exec"
+)
 
 func setHiddenAttribute(dir string) {
 }
+
+func hideWindow(cmd *exec.Cmd) {
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"log"
+	"os/exec"
 	"runtime"
 	"syscall"
 )
@@ -19,3 +20,8 @@ func setHiddenAttribute(dir string) {
 		}
 	}
 }
+
+// hideWindow keeps the child process from opening a console window
+func hideWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
@@ -5,36 +5,117 @@ package main
 import (
 	"errors"
 	"net"
+	"sort"
+	"strings"
 )
 
-// LocalIP get the host machine local IP address
-func LocalIP() (net.IP, error) {
+// LocalIPs get all non-loopback IPv4 and IPv6 addresses of the host machine.
+// IPv4 comes before IPv6, and private addresses before public ones.
+func LocalIPs() ([]net.IP, error) {
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	ips := filterIPs(addrs, isReachableIP)
+	if len(ips) == 0 {
+		return nil, errors.New("no IP")
+	}
+	return ips, nil
+}
+
+// LocalPrivateIPs get the private addresses of the host machine.
+// Public and temporary IPv6 addresses come and go, so only these go in the certificate.
+func LocalPrivateIPs() ([]net.IP, error) {
+	addrs, err := interfaceAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	ips := filterIPs(addrs, func(ip net.IP) bool {
+		return isReachableIP(ip) && isPrivateIP(ip)
+	})
+	if len(ips) == 0 {
+		return nil, errors.New("no private IP")
+	}
+	return ips, nil
+}
+
+// interfaceAddrs returns the addresses of interfaces other devices can reach us on
+func interfaceAddrs() ([]net.Addr, error) {
 	ifaces, err := net.Interfaces()
 	if err != nil {
 		return nil, err
 	}
-	for _, i := range ifaces {
-		addrs, err := i.Addrs()
+
+	var addrs []net.Addr
+	for _, i := range reachableInterfaces(ifaces) {
+		ifaceAddrs, err := i.Addrs()
 		if err != nil {
 			return nil, err
 		}
+		addrs = append(addrs, ifaceAddrs...)
+	}
 
-		for _, addr := range addrs {
-			var ip net.IP
-			switch v := addr.(type) {
-			case *net.IPNet:
-				ip = v.IP
-			case *net.IPAddr:
-				ip = v.IP
-			}
-
-			if isPrivateIP(ip) {
-				return ip, nil
-			}
+	return addrs, nil
+}
+
+// reachableInterfaces skips interfaces that are down, loopback or Docker's
+func reachableInterfaces(ifaces []net.Interface) []net.Interface {
+	var reachable []net.Interface
+	for _, i := range ifaces {
+		if i.Flags&net.FlagUp == 0 || i.Flags&net.FlagLoopback != 0 || isDockerInterface(i.Name) {
+			continue
 		}
+		reachable = append(reachable, i)
 	}
+	return reachable
+}
+
+// filterIPs returns the IPs of addrs accepted by keep, sorted by ipRank
+func filterIPs(addrs []net.Addr, keep func(net.IP) bool) []net.IP {
+	var ips []net.IP
+	for _, addr := range addrs {
+		var ip net.IP
+		switch v := addr.(type) {
+		case *net.IPNet:
+			ip = v.IP
+		case *net.IPAddr:
+			ip = v.IP
+		}
+
+		if ip != nil && keep(ip) {
+			ips = append(ips, ip)
+		}
+	}
+
+	sort.SliceStable(ips, func(i, j int) bool {
+		return ipRank(ips[i]) < ipRank(ips[j])
+	})
+
+	return ips
+}
+
+// ipRank orders IPv4 before IPv6, then private before public
+func ipRank(ip net.IP) int {
+	rank := 0
+	if ip.To4() == nil {
+		rank += 2
+	}
+	if !isPrivateIP(ip) {
+		rank++
+	}
+	return rank
+}
+
+// isReachableIP reports whether other devices could reach the host at ip
+func isReachableIP(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsUnspecified() && !ip.IsMulticast()
+}
 
-	return nil, errors.New("no IP")
+// isDockerInterface reports whether name is a Docker bridge or container interface
+func isDockerInterface(name string) bool {
+	return strings.HasPrefix(name, "docker") || strings.HasPrefix(name, "br-") || strings.HasPrefix(name, "veth")
 }
 
 func isPrivateIP(ip net.IP) bool {
@@ -47,6 +128,7 @@ func isPrivateIP(ip net.IP) bool {
 		"10.0.0.0/8",     // RFC1918
 		"172.16.0.0/12",  // RFC1918
 		"192.168.0.0/16", // RFC1918
+		"fc00::/7",       // RFC4193
 	} {
 		_, block, _ := net.ParseCIDR(cidr)
 		privateIPBlocks = append(privateIPBlocks, block)
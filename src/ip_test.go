@@ -0,0 +1,81 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestFilterIPs(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("203.0.113.5"), Mask: net.CIDRMask(24, 32)},
+		&net.IPNet{IP: net.ParseIP("fd00::5"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPAddr{IP: net.ParseIP("192.168.1.10")},
+		&net.IPNet{IP: net.ParseIP("169.254.1.1"), Mask: net.CIDRMask(16, 32)},
+		// RFC1918 LAN in 172.16.0.0/12; Docker is skipped by interface name instead
+		&net.IPNet{IP: net.ParseIP("172.20.0.5"), Mask: net.CIDRMask(16, 32)},
+		&net.IPNet{IP: net.ParseIP("224.0.0.1"), Mask: net.CIDRMask(4, 32)},
+		&net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)},
+	}
+
+	tests := []struct {
+		name string
+		keep func(net.IP) bool
+		want []string
+	}{
+		{
+			name: "reachable",
+			keep: isReachableIP,
+			// IPv4 private, IPv4 public, IPv6 private, IPv6 public
+			want: []string{"192.168.1.10", "172.20.0.5", "203.0.113.5", "fd00::5", "2001:db8::1"},
+		},
+		{
+			name: "private",
+			keep: func(ip net.IP) bool { return isReachableIP(ip) && isPrivateIP(ip) },
+			want: []string{"192.168.1.10", "172.20.0.5", "fd00::5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ip := range filterIPs(addrs, tt.keep) {
+				got = append(got, ip.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterIPs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterIPsEmpty(t *testing.T) {
+	if ips := filterIPs(nil, isReachableIP); len(ips) != 0 {
+		t.Errorf("filterIPs(nil) = %v, want none", ips)
+	}
+}
+
+func TestReachableInterfaces(t *testing.T) {
+	up := net.FlagUp | net.FlagBroadcast | net.FlagMulticast
+	ifaces := []net.Interface{
+		{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback},
+		{Index: 2, Name: "eth0", Flags: up},
+		{Index: 3, Name: "wlan0", Flags: net.FlagBroadcast},
+		{Index: 4, Name: "docker0", Flags: up},
+		{Index: 5, Name: "br-1a2b3c4d5e6f", Flags: up},
+		{Index: 6, Name: "veth1234567", Flags: up},
+		{Index: 7, Name: "Wi-Fi", Flags: up},
+		{Index: 8, Name: "tailscale0", Flags: net.FlagUp | net.FlagPointToPoint},
+	}
+
+	var got []string
+	for _, i := range reachableInterfaces(ifaces) {
+		got = append(got, i.Name)
+	}
+	if want := []string{"eth0", "Wi-Fi", "tailscale0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("reachableInterfaces() = %v, want %v", got, want)
+	}
+}
@@ -1,64 +1,109 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 )
 
 func main() {
-	// HTTPS -> HTTP director
-	const publishSeverScheme = "http"
-	director := func(req *http.Request) {
-		req.URL.Scheme = publishSeverScheme
-		req.URL.Host = ":" + getPortNumber(publishSeverScheme)
+	if err := loadConfig(); err != nil {
+		log.Fatal(err)
+	}
+	if config.PrintConfig {
+		if err := printConfig(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	// ReverseProxy
-	rp := &httputil.ReverseProxy{Director: director}
+	// HTTPS -> HTTP reverse proxy
+	const publishSeverScheme = "http"
+	rp := newReverseProxy(getPortNumber(publishSeverScheme), config.AllowedOrigins)
+
+	// Routes
+	mux := http.NewServeMux()
+	if config.TURNSecret != "" {
+		mux.Handle("/api/turn", turnHandler(config.TURNURLs, config.TURNSecret, config.TURNTTL, config.AllowedOrigins))
+	}
+	mux.Handle("/", rp)
 
 	// ReverseProxy server
 	const ReverseProxyServerScheme = "https"
-	httpsServer := http.Server{
-		Addr:    ":" + getPortNumber(ReverseProxyServerScheme),
-		Handler: rp,
-	}
+	httpsServer := newHTTPSServer(":"+getPortNumber(ReverseProxyServerScheme), mux)
 
 	dir := ".certs"
 	certPath := filepath.Join(dir, "cert.pem")
 	keyPath := filepath.Join(dir, "key.pem")
 
-	// Check if cert.pem and key.pem exist
-	_, certErr := os.Stat(certPath)
-	_, keyErr := os.Stat(keyPath)
-	if os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
-		// Generate self-signed certificate
-		generateCert()
+	if config.TLSCert != "" {
+		// Use the provided certificate
+		certPath, keyPath = config.TLSCert, config.TLSKey
+		if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+			log.Fatalf("Failed to load -tls-cert/-tls-key: %v", err)
+		}
+	} else {
+		// Check if cert.pem and key.pem exist
+		_, certErr := os.Stat(certPath)
+		_, keyErr := os.Stat(keyPath)
+		if os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
+			// Generate self-signed certificate
+			generateCert(dir, config.CertAlgo)
+		} else if reason := certRenewalReason(certPath); reason != "" {
+			// Regenerate expired or outdated certificate
+			log.Printf("Regenerating certificate: %s", reason)
+			generateCert(dir, config.CertAlgo)
+		}
+	}
+
+	// Download MediaMTX if it is missing or not the wanted version
+	if err := ensureMediaMTX(config.MediaMTXVersion, downloadMediaMTX); err != nil {
+		log.Fatalf("Failed to download MediaMTX: %v", err)
 	}
 
-	// Check if mediamtx folder exists
-	_, err := os.Stat("mediamtx")
-	if os.IsNotExist(err) {
-		downloadMediaMTX()
+	// Verify MediaMTX binary before executing it
+	if err := verifyMediaMTX(); err != nil {
+		log.Fatalf("MediaMTX verification failed: %v (remove the mediamtx folder to download it again)", err)
+	}
+
+	// MediaMTX settings
+	mediaMTXEnv, err := buildMediaMTXEnv(config, certPath, keyPath)
+	if err != nil {
+		log.Fatalf("Failed to configure MediaMTX: %v", err)
 	}
 
 	// Start MediaMTX server
 	go func() {
-		launchMediaMTX()
+		launchMediaMTX(mediaMTXEnv)
 	}()
 
 	// Open browser
-	openBrowser()
+	if config.NoBrowser {
+		fmt.Println("Open", publishUrl(), "to start casting")
+	} else {
+		openBrowser()
+	}
 
-	// Show IP address
-	ip, err := LocalIP()
+	// Show IP addresses
+	ips, err := LocalIPs()
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("https://" + ip.String() + ":" + getPortNumber(ReverseProxyServerScheme))
+	cert, err := loadCert(certPath)
+	if err != nil {
+		log.Printf("Failed to load certificate: %v", err)
+	}
+	for _, line := range httpsURLs(ips, cert, getPortNumber(ReverseProxyServerScheme)) {
+		fmt.Println(line)
+	}
 
 	// Start HTTPS server
 	fmt.Println("Server started at", "http://localhost:"+getPortNumber(publishSeverScheme))
@@ -67,10 +112,104 @@ func main() {
 	}
 }
 
+// buildMediaMTXEnv returns the MediaMTX settings for c, passed as MTX_* environment variables.
+// certPath and keyPath are the HTTPS certificate, used for RTSPS unless c has its own.
+func buildMediaMTXEnv(c Config, certPath string, keyPath string) ([]string, error) {
+	// Overrides the address in an existing mediamtx.yml.
+	// Other devices go through the HTTPS proxy, which enforces -allowed-origins.
+	env := []string{
+		"MTX_WEBRTCADDRESS=127.0.0.1:" + strconv.Itoa(c.HTTPPort),
+		"MTX_WEBRTCALLOWORIGIN=" + mediaMTXAllowOrigin(c.AllowedOrigins, c.HTTPSPort),
+	}
+
+	// RTSP timeouts, MediaMTX defaults unless set
+	if c.ReadTimeout > 0 {
+		env = append(env, "MTX_READTIMEOUT="+c.ReadTimeout.String())
+	}
+	if c.WriteTimeout > 0 {
+		env = append(env, "MTX_WRITETIMEOUT="+c.WriteTimeout.String())
+	}
+
+	// TURN
+	if c.TURNSecret != "" {
+		env = append(env, turnEnv(c.TURNURLs, c.TURNSecret)...)
+	}
+
+	// RTSPS
+	if c.RTSPS || c.RTSPSOnly {
+		rtspsCertPath, rtspsKeyPath := certPath, keyPath
+		if c.RTSPSCert != "" {
+			rtspsCertPath, rtspsKeyPath = c.RTSPSCert, c.RTSPSKey
+		}
+
+		rtsps, err := rtspsEnv(rtspsCertPath, rtspsKeyPath, c.RTSPSOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure RTSPS: %w", err)
+		}
+		env = append(env, rtsps...)
+	}
+
+	return env, nil
+}
+
+// httpsURLs returns the proxy URL for each of ips,
+// marking the ones cert doesn't cover as failing the browser's name check
+func httpsURLs(ips []net.IP, cert *x509.Certificate, port string) []string {
+	var urls []string
+	for _, ip := range ips {
+		url := "https://" + net.JoinHostPort(ip.String(), port)
+		if cert != nil && !certHasIP(cert, ip) {
+			url += " (not covered by the certificate)"
+		}
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// newReverseProxy returns a proxy to the MediaMTX WebRTC server on port,
+// allowing cross-origin requests from allowedOrigins only
+func newReverseProxy(port string, allowedOrigins []string) *httputil.ReverseProxy {
+	// HTTPS -> HTTP director
+	director := func(req *http.Request) {
+		req.URL.Scheme = "http"
+		req.URL.Host = "127.0.0.1:" + port
+	}
+
+	return &httputil.ReverseProxy{
+		Director: director,
+		Transport: &http.Transport{
+			DialContext:           (&net.Dialer{Timeout: 5 * time.Second}).DialContext,
+			ResponseHeaderTimeout: 15 * time.Second,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		// MediaMTX allows any origin; limit it to allowedOrigins
+		ModifyResponse: func(res *http.Response) error {
+			setCORSHeaders(res.Header, res.Request.Header.Get("Origin"), allowedOrigins)
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			log.Printf("Proxy error for %s: %v", req.URL.Path, err)
+			http.Error(w, "MediaMTX is not responding", http.StatusBadGateway)
+		},
+	}
+}
+
+// newHTTPSServer returns the server handling HTTPS requests on addr
+func newHTTPSServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+}
+
 func getPortNumber(scheme string) string {
 	if scheme == "http" {
-		return "8889"
+		return strconv.Itoa(config.HTTPPort)
 	} else {
-		return "8443"
+		return strconv.Itoa(config.HTTPSPort)
 	}
 }
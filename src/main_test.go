@@ -0,0 +1,262 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReverseProxySlowBackend(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer backend.Close()
+
+	_, port, err := net.SplitHostPort(backend.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const timeout = 50 * time.Millisecond
+	rp := newReverseProxy(port, nil)
+	rp.Transport.(*http.Transport).ResponseHeaderTimeout = timeout
+
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	rp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "https://localhost/mystream/publish", nil))
+	elapsed := time.Since(start)
+
+	// Well under the backend's 5s, so the timeout is what ended the request
+	if elapsed > 20*timeout {
+		t.Errorf("proxy answered after %v, want about %v", elapsed, timeout)
+	}
+
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadGateway)
+	}
+	if body, _ := io.ReadAll(rec.Body); string(body) != "MediaMTX is not responding\n" {
+		t.Errorf("body = %q, want %q", body, "MediaMTX is not responding\n")
+	}
+}
+
+func TestReverseProxyDirectorPorts(t *testing.T) {
+	defer func(c Config) { config = c }(config)
+	config.HTTPPort = 9100
+	config.HTTPSPort = 9443
+
+	rp := newReverseProxy(getPortNumber("http"), nil)
+	req := httptest.NewRequest(http.MethodGet, "https://192.168.1.10:9443/mystream/publish", nil)
+	rp.Director(req)
+
+	if req.URL.Scheme != "http" || req.URL.Host != "127.0.0.1:9100" {
+		t.Errorf("director rewrote to %s://%s, want http://127.0.0.1:9100", req.URL.Scheme, req.URL.Host)
+	}
+	if got := getPortNumber("https"); got != "9443" {
+		t.Errorf("getPortNumber(https) = %q, want %q", got, "9443")
+	}
+}
+
+func TestHTTPSServer(t *testing.T) {
+	dir := t.TempDir()
+	generateCert(dir, "ecdsa")
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newHTTPSServer(listener.Addr().String(), http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		io.WriteString(w, "camcast")
+	}))
+	done := make(chan error, 1)
+	go func() {
+		done <- server.ServeTLS(listener, certPath, keyPath)
+	}()
+	defer func() {
+		server.Close()
+		if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+			t.Error(err)
+		}
+	}()
+
+	// Trust only the generated certificate
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(certPEM) {
+		t.Fatal("failed to parse cert.pem")
+	}
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}},
+		Timeout:   5 * time.Second,
+	}
+
+	res, err := client.Get("https://" + listener.Addr().String() + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK || string(body) != "camcast" {
+		t.Errorf("got %s %q, want 200 OK %q", res.Status, body, "camcast")
+	}
+}
+
+func TestBuildMediaMTXEnvTimeouts(t *testing.T) {
+	tests := []struct {
+		name         string
+		readTimeout  time.Duration
+		writeTimeout time.Duration
+		want         []string
+		wantAbsent   []string
+	}{
+		{"unset", 0, 0, nil, []string{"MTX_READTIMEOUT", "MTX_WRITETIMEOUT"}},
+		{"read only", 30 * time.Second, 0, []string{"MTX_READTIMEOUT=30s"}, []string{"MTX_WRITETIMEOUT"}},
+		{"both", 30 * time.Second, 2 * time.Minute, []string{"MTX_READTIMEOUT=30s", "MTX_WRITETIMEOUT=2m0s"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{HTTPPort: 9100, ReadTimeout: tt.readTimeout, WriteTimeout: tt.writeTimeout}
+			env, err := buildMediaMTXEnv(c, "cert.pem", "key.pem")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Contains(env, "MTX_WEBRTCADDRESS=127.0.0.1:9100") {
+				t.Errorf("env %v lacks MTX_WEBRTCADDRESS=127.0.0.1:9100", env)
+			}
+			for _, want := range tt.want {
+				if !slices.Contains(env, want) {
+					t.Errorf("env %v lacks %s", env, want)
+				}
+			}
+			for _, absent := range tt.wantAbsent {
+				for _, variable := range env {
+					if strings.HasPrefix(variable, absent+"=") {
+						t.Errorf("env has %s, want it unset", variable)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReverseProxyCORS(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowed         []string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{"same origin", nil, "", "", ""},
+		{"disallowed", []string{"https://example.com"}, "https://evil.example", "", ""},
+		{"allowed", []string{"https://example.com"}, "https://example.com", "https://example.com", "true"},
+		{"allowed case-insensitive", []string{"https://Example.com"}, "https://example.com", "https://example.com", "true"},
+		{"any", []string{"*"}, "https://evil.example", "*", ""},
+		{"any without origin", []string{"*"}, "", "*", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rp := newReverseProxy("8889", tt.allowed)
+
+			req := httptest.NewRequest(http.MethodPost, "https://localhost:8443/mystream/whip", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			// MediaMTX allows any origin by default
+			res := &http.Response{Header: http.Header{}, Request: req}
+			res.Header.Set("Access-Control-Allow-Origin", "*")
+			res.Header.Set("Access-Control-Allow-Credentials", "true")
+
+			if err := rp.ModifyResponse(res); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := res.Header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := res.Header.Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestBuildMediaMTXEnvAllowOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		want    string
+	}{
+		{"same origin", nil, "https://localhost:9443"},
+		{"one origin", []string{"https://example.com"}, "https://example.com"},
+		{"any", []string{"*"}, "*"},
+		{"several origins", []string{"https://a.example", "https://b.example"}, "https://localhost:9443"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{HTTPPort: 9100, HTTPSPort: 9443, AllowedOrigins: tt.allowed}
+			env, err := buildMediaMTXEnv(c, "cert.pem", "key.pem")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if want := "MTX_WEBRTCALLOWORIGIN=" + tt.want; !slices.Contains(env, want) {
+				t.Errorf("env %v lacks %s", env, want)
+			}
+		})
+	}
+}
+
+func TestHTTPSURLs(t *testing.T) {
+	cert := &x509.Certificate{IPAddresses: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("192.168.1.10")}}
+	ips := []net.IP{net.ParseIP("192.168.1.10"), net.ParseIP("203.0.113.5"), net.ParseIP("2001:db8::1")}
+
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want []string
+	}{
+		{"covered and not covered", cert, []string{
+			"https://192.168.1.10:8443",
+			"https://203.0.113.5:8443 (not covered by the certificate)",
+			"https://[2001:db8::1]:8443 (not covered by the certificate)",
+		}},
+		{"unknown certificate", nil, []string{
+			"https://192.168.1.10:8443",
+			"https://203.0.113.5:8443",
+			"https://[2001:db8::1]:8443",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := httpsURLs(ips, tt.cert, "8443"); !slices.Equal(got, tt.want) {
+				t.Errorf("httpsURLs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,74 +3,359 @@ package main
 import (
 	"archive/tar"
 	"archive/zip"
-	"bytes"
+	"bufio"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
-	"syscall"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func downloadMediaMTX() {
-	os.Mkdir("mediamtx", 0755)
-	os.Chdir("mediamtx")
+const (
+	// Directory MediaMTX is extracted to
+	mediaMTXDir = "mediamtx"
+	// Checksums published with each MediaMTX release
+	releaseChecksumsFile = "checksums.sha256"
+	// Checksum of the extracted binary, recorded for re-verification on launch
+	binaryChecksumFile = "mediamtx.sha256"
+	// Release tag of the extracted binary
+	versionFile = "VERSION"
+	// MediaMTX configuration, generated by camcast
+	configFile = "mediamtx.yml"
+)
 
+// mediaMTXConfig enables only what camcast uses:
+// WebRTC publishing from the browser and RTSP reading.
+const mediaMTXConfig = `# Generated by camcast. Delete this file to regenerate it.
+logLevel: info
+
+# Reading
+rtsp: yes
+rtspAddress: :8554
+
+# Publishing
+webrtc: yes
+webrtcAddress: 127.0.0.1:%s
+
+# Unused
+rtmp: no
+hls: no
+srt: no
+api: no
+metrics: no
+pprof: no
+playback: no
+
+paths:
+  mystream:
+`
+
+// downloadMediaMTX downloads and installs the given release tag, or the latest release if version is empty
+func downloadMediaMTX(version string) error {
 	fmt.Println("Downloading MediaMTX...")
-	url := generateDownloadUrl()
+	url, tag, err := generateDownloadUrl(version)
+	if err != nil {
+		return fmt.Errorf("failed to resolve download URL: %w", err)
+	}
 
 	// Download MediaMTX
-	res, err := http.Get(url)
+	// The archive is streamed to a temp file instead of memory for small devices.
+	archive, err := os.CreateTemp("", "mediamtx-*-"+path.Base(url))
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
-	defer res.Body.Close()
+	defer os.Remove(archive.Name())
+	defer archive.Close()
 
-	body, err := io.ReadAll(res.Body)
+	actual, size, err := downloadFile(url, archive)
 	if err != nil {
-		fmt.Println(err)
+		return fmt.Errorf("failed to download %s: %w", path.Base(url), err)
 	}
 
-	// Decompress MediaMTX
-	if filepath.Ext(url) == ".zip" {
-		unZip(body)
-	} else if filepath.Ext(url) == ".gz" {
-		unTarGz(body)
+	// Verify archive checksum
+	expected, err := fetchChecksum(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch checksum: %w", err)
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path.Base(url), expected, actual)
+	}
+
+	// Install MediaMTX
+	if err := installMediaMTX(archive, size, path.Base(url), tag); err != nil {
+		return err
 	}
 
 	fmt.Println("MediaMTX downloaded.")
-	os.Chdir("..")
+	fmt.Println("MediaMTX version:", tag)
+	return nil
 }
 
-func unZip(body []byte) {
-	zipReader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+// installMediaMTX extracts the release archive called name to a temp directory
+// that replaces mediaMTXDir only on success
+func installMediaMTX(archive io.ReaderAt, size int64, name string, tag string) error {
+	// Temp directory next to mediaMTXDir so it can be renamed into place
+	tmpDir, err := os.MkdirTemp(".", mediaMTXDir+"-download-")
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Decompress MediaMTX
+	if filepath.Ext(name) == ".zip" {
+		err = unZip(archive, size, tmpDir)
+	} else if filepath.Ext(name) == ".gz" {
+		err = unTarGz(io.NewSectionReader(archive, 0, size), tmpDir)
+	} else {
+		err = fmt.Errorf("unknown archive type: %s", name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+
+	// Keep the current config, or generate one
+	if err := copyExistingFile(filepath.Join(mediaMTXDir, configFile), filepath.Join(tmpDir, configFile)); err != nil {
+		return fmt.Errorf("failed to keep %s: %w", configFile, err)
+	}
+	if err := writeMediaMTXConfig(tmpDir); err != nil {
+		return fmt.Errorf("failed to write %s: %w", configFile, err)
+	}
+
+	// Record binary checksum
+	binaryChecksum, err := fileSHA256(filepath.Join(tmpDir, mediaMTXBinary()))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found in %s", mediaMTXBinary(), name)
+	} else if err != nil {
+		return fmt.Errorf("failed to hash binary: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, binaryChecksumFile), []byte(binaryChecksum+"\n"), 0644); err != nil {
+		return err
+	}
+
+	// Record version
+	if err := os.WriteFile(filepath.Join(tmpDir, versionFile), []byte(tag+"\n"), 0644); err != nil {
+		return err
+	}
+
+	// Move into place
+	if err := replaceDir(tmpDir, mediaMTXDir); err != nil {
+		return fmt.Errorf("failed to install MediaMTX: %w", err)
+	}
+
+	return nil
+}
+
+// replaceDir renames src to dst, replacing any existing dst
+func replaceDir(src string, dst string) error {
+	// Windows can't rename onto an existing directory, so move it aside first
+	old := dst + ".old"
+	if err := os.RemoveAll(old); err != nil {
+		return err
+	}
+	if err := os.Rename(dst, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Rename(src, dst); err != nil {
+		// Restore the previous install
+		os.Rename(old, dst)
+		return err
+	}
+
+	return os.RemoveAll(old)
+}
+
+// copyExistingFile copies src to dst if src exists
+func copyExistingFile(src string, dst string) error {
+	data, err := os.ReadFile(src)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
+
+// Retry policy for HTTP requests
+const (
+	httpAttempts = 3
+	// Longer Retry-After waits (e.g. GitHub rate-limit resets) fail instead
+	maxRetryAfter = time.Minute
+)
+
+// First retry delay, doubled on each attempt
+var httpRetryBackoff = time.Second
+
+// httpClient is shared by all MediaMTX downloads and honors HTTP(S)_PROXY and NO_PROXY
+var httpClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+	// Upper bound for a whole request including the archive body
+	Timeout: 10 * time.Minute,
+}
+
+// httpGet fetches target, retrying network errors, rate limiting and server errors
+// with exponential backoff or the server's Retry-After.
+// Any status other than 200 OK is returned as an error.
+func httpGet(target string) (*http.Response, error) {
+	backoff := httpRetryBackoff
+	for attempt := 1; ; attempt++ {
+		wait := backoff
+		backoff *= 2
+
+		res, err := httpClient.Get(target)
+		if err == nil && res.StatusCode == http.StatusOK {
+			return res, nil
+		}
+		if err == nil {
+			res.Body.Close()
+			err = fmt.Errorf("GET %s: %s", target, res.Status)
+
+			retryAfter, hasRetryAfter := parseRetryAfter(res.Header.Get("Retry-After"))
+			if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < http.StatusInternalServerError && !hasRetryAfter {
+				// Not transient
+				return nil, err
+			}
+			if hasRetryAfter {
+				if retryAfter > maxRetryAfter {
+					return nil, fmt.Errorf("%w (retry after %v)", err, retryAfter.Round(time.Second))
+				}
+				wait = retryAfter
+			}
+		}
+
+		if attempt == httpAttempts {
+			return nil, err
+		}
+		fmt.Printf("%v (attempt %d/%d), retrying in %v...\n", err, attempt, httpAttempts, wait)
+		time.Sleep(wait)
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+const (
+	// Progress is reported every progressPercentStep percent,
+	// or every progressByteStep bytes when the size is unknown
+	progressPercentStep = 5
+	progressByteStep    = 1 << 20
+)
+
+// progressReader prints download progress to out as it is read
+type progressReader struct {
+	reader io.Reader
+	out    io.Writer
+	total  int64
+	read   int64
+	next   int64
+}
+
+func newProgressReader(reader io.Reader, total int64, out io.Writer) *progressReader {
+	next := int64(progressByteStep)
+	if total > 0 {
+		next = progressPercentStep
+	}
+	return &progressReader{reader: reader, out: out, total: total, next: next}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.read += int64(n)
+
+	if p.total > 0 {
+		percent := p.read * 100 / p.total
+		if percent >= p.next {
+			fmt.Fprintf(p.out, "Downloading MediaMTX... %d%%\n", percent)
+			p.next = percent - percent%progressPercentStep + progressPercentStep
+		}
+	} else if p.read >= p.next {
+		fmt.Fprintf(p.out, "Downloading MediaMTX... %.1f MB\n", float64(p.read)/(1<<20))
+		p.next = p.read - p.read%progressByteStep + progressByteStep
+	}
+
+	return n, err
+}
+
+// downloadFile writes the body at target to file and returns its SHA-256 and size
+func downloadFile(target string, file io.Writer) (string, int64, error) {
+	res, err := httpGet(target)
+	if err != nil {
+		return "", 0, err
+	}
+	defer res.Body.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), newProgressReader(res.Body, res.ContentLength, os.Stdout))
+	if err != nil {
+		return "", 0, err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+func unZip(archive io.ReaderAt, size int64, dir string) error {
+	zipReader, err := zip.NewReader(archive, size)
+	if err != nil {
+		return err
 	}
 
 	for _, zipFile := range zipReader.File {
+		// The bundled config is replaced by camcast's own
+		if zipFile.FileInfo().IsDir() || zipFile.Name == configFile {
+			continue
+		}
+
 		f, err := zipFile.Open()
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
-		defer f.Close()
 
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(f)
-		createWriteFile(zipFile.Name, buf.Bytes())
+		err = createWriteFile(dir, zipFile.Name, f, zipFile.Mode().Perm())
+		f.Close()
+		if err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
-func unTarGz(body []byte) {
-	gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+func unTarGz(archive io.Reader, dir string) error {
+	gzipReader, err := gzip.NewReader(archive)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
+	defer gzipReader.Close()
 
 	tarReader := tar.NewReader(gzipReader)
 	for {
@@ -79,104 +364,355 @@ func unTarGz(body []byte) {
 			break
 		}
 		if err != nil {
-			fmt.Println(err)
+			return err
 		}
 
-		buf := new(bytes.Buffer)
-		buf.ReadFrom(tarReader)
-		createWriteFile(header.Name, buf.Bytes())
+		// Only regular files are extracted.
+		// The bundled config is replaced by camcast's own.
+		if header.Typeflag != tar.TypeReg || header.Name == configFile {
+			continue
+		}
+
+		if err := createWriteFile(dir, header.Name, tarReader, header.FileInfo().Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMediaMTXConfig writes camcast's MediaMTX config to dir unless one already exists
+func writeMediaMTXConfig(dir string) error {
+	configPath := filepath.Join(dir, configFile)
+	if _, err := os.Stat(configPath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
 	}
-	os.Chmod("mediamtx", 0755)
 
-	gzipReader.Close()
+	content := fmt.Sprintf(mediaMTXConfig, getPortNumber("http"))
+	return os.WriteFile(configPath, []byte(content), 0644)
 }
 
-func createWriteFile(name string, body []byte) {
-	file, err := os.Create(name)
+// createWriteFile writes an archive entry under dir, creating parent directories as needed
+func createWriteFile(dir string, name string, body io.Reader, perm os.FileMode) error {
+	target, err := extractPath(dir, name)
 	if err != nil {
-		fmt.Println(err)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if perm == 0 {
+		perm = 0644
+	}
+	file, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
 	}
 	defer file.Close()
 
-	_, err = file.Write(body)
+	_, err = io.Copy(file, body)
+	return err
+}
+
+// extractPath joins an archive entry name under dir, rejecting names that would escape it
+func extractPath(dir string, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("refusing to extract entry with empty name")
+	}
+	// Windows paths are rejected on every platform, archives are shared between them
+	hasDrive := len(name) >= 2 && name[1] == ':'
+	if strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) || hasDrive ||
+		filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("refusing to extract %q: absolute path", name)
+	}
+
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if part == ".." {
+			return "", fmt.Errorf("refusing to extract %q: path traversal", name)
+		}
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(name)), nil
+}
+
+// fetchChecksum returns the published SHA-256 of the archive at downloadUrl
+func fetchChecksum(downloadUrl string) (string, error) {
+	checksumsUrl := downloadUrl[:strings.LastIndex(downloadUrl, "/")+1] + releaseChecksumsFile
+
+	res, err := httpGet(checksumsUrl)
 	if err != nil {
-		fmt.Println(err)
+		return "", err
+	}
+	defer res.Body.Close()
+
+	// Each line is "<sha256>  <file name>"
+	packageName := path.Base(downloadUrl)
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == packageName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("no checksum for %s in %s", packageName, releaseChecksumsFile)
+}
+
+func fileSHA256(name string) (string, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
 	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
+// verifyMediaMTX checks the binary against the checksum recorded at download time
+func verifyMediaMTX() error {
+	expected, err := os.ReadFile(filepath.Join(mediaMTXDir, binaryChecksumFile))
+	if os.IsNotExist(err) {
+		return errors.New("no recorded checksum")
+	} else if err != nil {
+		return err
+	}
+
+	actual, err := fileSHA256(filepath.Join(mediaMTXDir, mediaMTXBinary()))
+	if err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(string(expected)) != actual {
+		return fmt.Errorf("checksum mismatch for %s", mediaMTXBinary())
+	}
+
+	return nil
+}
+
+// MediaMTX GitHub repository
+const (
+	mediaMTXOwner = "bluenviron"
+	mediaMTXRepo  = "mediamtx"
+)
+
+// Base URL of release downloads
+var githubUrl = "https://github.com/"
+
 type Release struct {
 	TagName string `json:"tag_name"`
 }
 
-func generateDownloadUrl() string {
-	const owner = "bluenviron"
-	const repo = "mediamtx"
+// generateDownloadUrl returns the package URL and release tag for version, or for the latest release if version is empty
+func generateDownloadUrl(version string) (string, string, error) {
+	tag := normalizeTag(version)
+	if tag == "" {
+		latestTag, err := latestMediaMTXTag()
+		if err != nil {
+			return "", "", err
+		}
+		tag = latestTag
+	}
 
-	// Fetch latest release
-	githubReleasesApiUrl, err := url.JoinPath("https://api.github.com/repos/", owner, "/", repo, "/releases/latest")
+	downloadUrlBase, err := url.JoinPath(githubUrl, mediaMTXOwner, mediaMTXRepo, "/releases/download", tag)
 	if err != nil {
-		fmt.Println(err)
+		return "", "", err
 	}
 
-	res, err := http.Get(githubReleasesApiUrl)
+	downloadUrlSuffix, err := generateSuffixUrl(runtime.GOOS, runtime.GOARCH, goarm())
 	if err != nil {
-		fmt.Println(err)
+		return "", "", err
+	}
+	downloadPackageUrl := mediaMTXRepo + "_" + tag + "_" + downloadUrlSuffix
+
+	downloadUrl, err := url.JoinPath(downloadUrlBase, downloadPackageUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	return downloadUrl, tag, nil
+}
+
+// latestMediaMTXTag fetches the tag of the latest MediaMTX release
+func latestMediaMTXTag() (string, error) {
+	githubReleasesApiUrl, err := url.JoinPath("https://api.github.com/repos/", mediaMTXOwner, "/", mediaMTXRepo, "/releases/latest")
+	if err != nil {
+		return "", err
+	}
+
+	res, err := httpGet(githubReleasesApiUrl)
+	if err != nil {
+		return "", err
 	}
 	defer res.Body.Close()
 
 	var release Release
 	err = json.NewDecoder(res.Body).Decode(&release)
 	if err != nil {
-		fmt.Println(err)
+		return "", fmt.Errorf("failed to decode latest release: %w", err)
+	}
+	if release.TagName == "" {
+		return "", errors.New("latest release has no tag")
 	}
 
-	downloadUrlBase, err := url.JoinPath("https://github.com/", owner, repo, "/releases/latest/download")
-	if err != nil {
-		fmt.Println(err)
+	return normalizeTag(release.TagName), nil
+}
+
+// normalizeTag returns version with the "v" prefix used by MediaMTX release tags
+func normalizeTag(version string) string {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return ""
 	}
+	return "v" + strings.TrimPrefix(version, "v")
+}
 
-	downloadUrlSuffix := generateSuffixUrl()
-	downloadPackageUrl := repo + "_" + release.TagName + "_" + downloadUrlSuffix
+// ensureMediaMTX calls download with the wanted release tag unless the installed copy
+// already matches version, or the latest release if version is empty
+func ensureMediaMTX(version string, download func(tag string) error) error {
+	tag := normalizeTag(version)
+	if tag == "" {
+		latestTag, err := latestMediaMTXTag()
+		if err != nil {
+			if !mediaMTXInstalled() {
+				return err
+			}
+			// Offline or rate-limited; keep the installed copy
+			fmt.Printf("Failed to check for MediaMTX updates: %v\n", err)
+			return nil
+		}
+		tag = latestTag
+	}
 
-	downloadUrl, err := url.JoinPath(downloadUrlBase, downloadPackageUrl)
-	if err != nil {
-		fmt.Println(err)
+	if mediaMTXInstalled() && installedMediaMTXVersion() == tag {
+		return nil
 	}
 
-	return downloadUrl
+	return download(tag)
 }
 
-func generateSuffixUrl() string {
-	os := runtime.GOOS
-	arch := runtime.GOARCH
+// mediaMTXInstalled reports whether a non-empty MediaMTX binary is present with its recorded
+// checksum and version. Installs from before these were recorded count as missing.
+func mediaMTXInstalled() bool {
+	info, err := os.Stat(filepath.Join(mediaMTXDir, mediaMTXBinary()))
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+		return false
+	}
 
-	if os == "windows" && arch == "amd64" {
-		return "windows_amd64.zip"
-	} else if os == "darwin" && arch == "amd64" {
-		return "darwin_amd64.tar.gz"
-	} else if os == "linux" && arch == "arm64" {
-		return "linux_arm64.tar.gz"
-	} else if os == "linux" && arch == "amd64" {
-		return "linux_amd64.tar.gz"
-	} else {
+	for _, name := range []string{binaryChecksumFile, versionFile} {
+		if _, err := os.Stat(filepath.Join(mediaMTXDir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// installedMediaMTXVersion returns the release tag of the downloaded MediaMTX, or "" if unknown
+func installedMediaMTXVersion() string {
+	version, err := os.ReadFile(filepath.Join(mediaMTXDir, versionFile))
+	if err != nil {
 		return ""
 	}
+	return strings.TrimSpace(string(version))
 }
 
-func launchMediaMTX() {
-	fmt.Println("Launching MediaMTX...")
+// generateSuffixUrl returns the MediaMTX package suffix for goos/goarch.
+// armVersion is the GOARM version, used on 32-bit ARM only.
+func generateSuffixUrl(goos string, goarch string, armVersion string) (string, error) {
+	if goos == "windows" && goarch == "amd64" {
+		return "windows_amd64.zip", nil
+	} else if goos == "windows" && goarch == "arm64" {
+		return "windows_arm64.zip", nil
+	} else if goos == "darwin" && goarch == "amd64" {
+		return "darwin_amd64.tar.gz", nil
+	} else if goos == "darwin" && goarch == "arm64" {
+		return "darwin_arm64.tar.gz", nil
+	} else if goos == "linux" && goarch == "arm" {
+		return "linux_armv" + armVersion + ".tar.gz", nil
+	} else if goos == "linux" && goarch == "arm64" {
+		return "linux_arm64.tar.gz", nil
+	} else if goos == "linux" && goarch == "amd64" {
+		return "linux_amd64.tar.gz", nil
+	} else if goos == "freebsd" && goarch == "amd64" {
+		return "freebsd_amd64.tar.gz", nil
+	} else if goos == "freebsd" && goarch == "arm64" {
+		return "freebsd_arm64.tar.gz", nil
+	} else {
+		return "", fmt.Errorf("unsupported platform: %s/%s", goos, goarch)
+	}
+}
 
-	var cmd *exec.Cmd
+// goarm returns the ARM version this binary was built for, "6" or "7"
+func goarm() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			// Value may carry a float mode suffix, e.g. "7,softfloat"
+			if setting.Key == "GOARM" && strings.HasPrefix(setting.Value, "7") {
+				return "7"
+			}
+		}
+	}
+
+	// ARMv6 builds also run on ARMv7
+	return "6"
+}
+
+func mediaMTXBinary() string {
 	if runtime.GOOS == "windows" {
-		cmd = exec.Command("./mediamtx.exe")
-		cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+		return "mediamtx.exe"
+	}
+	return "mediamtx"
+}
+
+// rtspsEnv returns MediaMTX settings serving RTSPS with the given certificate.
+// Plain RTSP stays available unless strict is set.
+func rtspsEnv(certPath string, keyPath string, strict bool) ([]string, error) {
+	// MediaMTX runs inside mediaMTXDir
+	certPath, err := filepath.Abs(certPath)
+	if err != nil {
+		return nil, err
+	}
+	keyPath, err = filepath.Abs(keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	env := []string{
+		"MTX_RTSPSERVERCERT=" + certPath,
+		"MTX_RTSPSERVERKEY=" + keyPath,
+	}
+	if strict {
+		// Strict encryption doesn't support UDP transports
+		env = append(env, "MTX_RTSPENCRYPTION=strict", "MTX_RTSPTRANSPORTS=tcp")
 	} else {
-		cmd = exec.Command("./mediamtx")
+		env = append(env, "MTX_RTSPENCRYPTION=optional")
 	}
 
+	return env, nil
+}
+
+// launchMediaMTX runs MediaMTX with env added to the current environment
+func launchMediaMTX(env []string) {
+	fmt.Println("Launching MediaMTX...")
+
+	cmd := exec.Command("./" + mediaMTXBinary())
+	hideWindow(cmd)
+	cmd.Env = append(os.Environ(), env...)
+
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	cmd.Dir = "mediamtx"
+	cmd.Dir = mediaMTXDir
 
 	err := cmd.Run()
 	if err != nil {
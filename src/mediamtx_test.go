@@ -0,0 +1,706 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// testProxy records the requests httpClient sends through HTTP(S)_PROXY
+var testProxy struct {
+	sync.Mutex
+	requests []string
+}
+
+func TestMain(m *testing.M) {
+	// http.ProxyFromEnvironment reads the environment once, so the proxy is set
+	// before any test runs. Requests to loopback test servers bypass it.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		testProxy.Lock()
+		testProxy.requests = append(testProxy.requests, req.Method+" "+req.Host)
+		testProxy.Unlock()
+
+		if req.Method == http.MethodConnect {
+			http.Error(w, "no tunnels", http.StatusForbidden)
+			return
+		}
+		io.WriteString(w, "via proxy")
+	}))
+	os.Setenv("HTTP_PROXY", proxy.URL)
+	os.Setenv("HTTPS_PROXY", proxy.URL)
+	os.Unsetenv("NO_PROXY")
+	os.Unsetenv("no_proxy")
+
+	code := m.Run()
+	proxy.Close()
+	os.Exit(code)
+}
+
+func TestProgressReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		total  int64
+		suffix string
+		last   float64
+	}{
+		{"known size", 1 << 20, 1 << 20, "%", 100},
+		{"unknown size", 3 << 20, -1, " MB", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			// Small reads so progress is reported along the way
+			body := iotest.HalfReader(bytes.NewReader(make([]byte, tt.size)))
+			n, err := io.Copy(io.Discard, newProgressReader(body, tt.total, &out))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != int64(tt.size) {
+				t.Fatalf("read %d bytes, want %d", n, tt.size)
+			}
+
+			var reported []float64
+			scanner := bufio.NewScanner(&out)
+			for scanner.Scan() {
+				value, ok := strings.CutPrefix(scanner.Text(), "Downloading MediaMTX... ")
+				if !ok || !strings.HasSuffix(value, tt.suffix) {
+					t.Fatalf("unexpected progress line %q", scanner.Text())
+				}
+				progress, err := strconv.ParseFloat(strings.TrimSuffix(value, tt.suffix), 64)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reported = append(reported, progress)
+			}
+
+			if len(reported) < 2 {
+				t.Fatalf("got progress %v, want several reports", reported)
+			}
+			for i := 1; i < len(reported); i++ {
+				if reported[i] <= reported[i-1] {
+					t.Fatalf("progress %v does not increase", reported)
+				}
+			}
+			if last := reported[len(reported)-1]; last != tt.last {
+				t.Errorf("last progress = %v, want %v", last, tt.last)
+			}
+		})
+	}
+}
+
+func TestGenerateSuffixUrl(t *testing.T) {
+	tests := []struct {
+		goos       string
+		goarch     string
+		armVersion string
+		want       string
+	}{
+		{"windows", "amd64", "", "windows_amd64.zip"},
+		{"windows", "arm64", "", "windows_arm64.zip"},
+		{"darwin", "amd64", "", "darwin_amd64.tar.gz"},
+		{"darwin", "arm64", "", "darwin_arm64.tar.gz"},
+		{"linux", "amd64", "", "linux_amd64.tar.gz"},
+		{"linux", "arm64", "", "linux_arm64.tar.gz"},
+		{"linux", "arm", "6", "linux_armv6.tar.gz"},
+		{"linux", "arm", "7", "linux_armv7.tar.gz"},
+		{"freebsd", "amd64", "", "freebsd_amd64.tar.gz"},
+		{"freebsd", "arm64", "", "freebsd_arm64.tar.gz"},
+		{"linux", "386", "", ""},
+		{"plan9", "amd64", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.goarch+tt.armVersion, func(t *testing.T) {
+			got, err := generateSuffixUrl(tt.goos, tt.goarch, tt.armVersion)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("generateSuffixUrl() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("generateSuffixUrl() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		wantErr bool
+	}{
+		{"file", "mediamtx", false},
+		{"nested directory", "lib/sub/file.txt", false},
+		{"parent", "../evil", true},
+		{"nested parent", "lib/../../evil", true},
+		{"backslash parent", `lib\..\..\evil`, true},
+		{"absolute", "/tmp/evil", true},
+		{"backslash absolute", `\evil`, true},
+		{"volume", `C:\evil`, true},
+	}
+
+	extractors := []struct {
+		name    string
+		extract func(t *testing.T, entry string, dir string) error
+	}{
+		{"zip", func(t *testing.T, entry string, dir string) error {
+			archive := zipArchive(t, map[string]string{entry: "content"})
+			return unZip(bytes.NewReader(archive), int64(len(archive)), dir)
+		}},
+		{"tar.gz", func(t *testing.T, entry string, dir string) error {
+			return unTarGz(bytes.NewReader(tarGzArchive(t, map[string]string{entry: "content"})), dir)
+		}},
+	}
+
+	for _, extractor := range extractors {
+		for _, tt := range tests {
+			t.Run(extractor.name+"/"+tt.name, func(t *testing.T) {
+				base := t.TempDir()
+				dir := filepath.Join(base, "out")
+
+				err := extractor.extract(t, tt.entry, dir)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatal("extracted an entry outside the directory")
+					}
+					if _, err := os.Stat(filepath.Join(base, "evil")); !os.IsNotExist(err) {
+						t.Error("evil was written outside the directory")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(tt.entry)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != "content" {
+					t.Errorf("content = %q, want %q", content, "content")
+				}
+			})
+		}
+	}
+}
+
+// zipArchive returns a zip archive of files, keyed by entry name
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// tarGzArchive returns a tar.gz archive of files, keyed by entry name
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buf)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for name, content := range files {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0755, Size: int64(len(content))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tarWriter, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDownloadFileRetry(t *testing.T) {
+	defer func(backoff time.Duration) { httpRetryBackoff = backoff }(httpRetryBackoff)
+	httpRetryBackoff = time.Millisecond
+
+	type response struct {
+		status     int
+		retryAfter string
+	}
+	tests := []struct {
+		name         string
+		responses    []response
+		wantErr      bool
+		wantRequests int
+	}{
+		{"ok", nil, false, 1},
+		{"server errors", []response{{http.StatusInternalServerError, ""}, {http.StatusBadGateway, ""}}, false, 3},
+		{"too many requests", []response{{http.StatusTooManyRequests, "0"}}, false, 2},
+		{"rate limited with retry after", []response{{http.StatusForbidden, "0"}}, false, 2},
+		{"retry after too long", []response{{http.StatusServiceUnavailable, "3600"}}, true, 1},
+		{"not found", []response{{http.StatusNotFound, ""}}, true, 1},
+		{"always failing", []response{{http.StatusInternalServerError, ""}, {http.StatusInternalServerError, ""}, {http.StatusInternalServerError, ""}}, true, httpAttempts},
+	}
+
+	const body = "mediamtx archive"
+	sum := sha256.Sum256([]byte(body))
+	wantHash := hex.EncodeToString(sum[:])
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				requests++
+				if requests <= len(tt.responses) {
+					res := tt.responses[requests-1]
+					if res.retryAfter != "" {
+						w.Header().Set("Retry-After", res.retryAfter)
+					}
+					w.WriteHeader(res.status)
+					return
+				}
+				io.WriteString(w, body)
+			}))
+			defer server.Close()
+
+			var file bytes.Buffer
+			hash, size, err := downloadFile(server.URL, &file)
+			if requests != tt.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, tt.wantRequests)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Error("downloadFile() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if file.String() != body || size != int64(len(body)) || hash != wantHash {
+				t.Errorf("downloadFile() = %q (%d bytes, %s), want %q (%d bytes, %s)", file.String(), size, hash, body, len(body), wantHash)
+			}
+		})
+	}
+}
+
+func TestHTTPClientProxy(t *testing.T) {
+	defer func(backoff time.Duration) { httpRetryBackoff = backoff }(httpRetryBackoff)
+	httpRetryBackoff = time.Millisecond
+
+	tests := []struct {
+		target      string
+		wantRequest string
+		wantErr     bool
+	}{
+		{"http://mediamtx.invalid/release.tar.gz", "GET mediamtx.invalid", false},
+		// The test proxy refuses to tunnel, but the CONNECT shows HTTPS_PROXY was used
+		{"https://mediamtx.invalid/release.tar.gz", "CONNECT mediamtx.invalid:443", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			testProxy.Lock()
+			testProxy.requests = nil
+			testProxy.Unlock()
+
+			var file bytes.Buffer
+			_, _, err := downloadFile(tt.target, &file)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("downloadFile() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && file.String() != "via proxy" {
+				t.Errorf("body = %q, want %q", file.String(), "via proxy")
+			}
+
+			testProxy.Lock()
+			defer testProxy.Unlock()
+			if len(testProxy.requests) == 0 || testProxy.requests[0] != tt.wantRequest {
+				t.Errorf("proxy got %v, want %q", testProxy.requests, tt.wantRequest)
+			}
+		})
+	}
+}
+
+func TestWriteMediaMTXConfig(t *testing.T) {
+	defer func(c Config) { config = c }(config)
+	config.HTTPPort = 9100
+
+	dir := t.TempDir()
+	if err := writeMediaMTXConfig(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, configFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Top-level "key: value" pairs; nested keys are kept with their indentation
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			t.Fatalf("invalid YAML line %q", line)
+		}
+		if _, dup := values[key]; dup {
+			t.Fatalf("duplicate key %q", key)
+		}
+		values[key] = strings.TrimSpace(value)
+	}
+
+	want := map[string]string{
+		"rtsp":          "yes",
+		"webrtc":        "yes",
+		"webrtcAddress": "127.0.0.1:9100",
+		"hls":           "no",
+		"rtmp":          "no",
+		"api":           "no",
+		"paths":         "",
+		"  mystream":    "",
+	}
+	for key, value := range want {
+		if got, ok := values[key]; !ok || got != value {
+			t.Errorf("%s = %q (set %v), want %q", key, got, ok, value)
+		}
+	}
+
+	t.Run("existing config", func(t *testing.T) {
+		const existing = "webrtcAddress: :1234\n"
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, configFile), []byte(existing), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := writeMediaMTXConfig(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, configFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != existing {
+			t.Errorf("existing config was overwritten with %q", content)
+		}
+	})
+}
+
+func TestEnsureMediaMTX(t *testing.T) {
+	tests := []struct {
+		name         string
+		binary       string
+		version      string
+		missing      string
+		want         string
+		wantDownload bool
+	}{
+		{name: "not installed", want: "v1.9.0", wantDownload: true},
+		{name: "stale version", binary: "mediamtx", version: "v1.8.0", want: "1.9.0", wantDownload: true},
+		{name: "unknown version", binary: "mediamtx", version: "v1.9.0", missing: versionFile, want: "v1.9.0", wantDownload: true},
+		{name: "no recorded checksum", binary: "mediamtx", version: "v1.9.0", missing: binaryChecksumFile, want: "v1.9.0", wantDownload: true},
+		{name: "empty binary", binary: "", version: "v1.9.0", want: "v1.9.0", wantDownload: true},
+		{name: "matching version", binary: "mediamtx", version: "v1.9.0", want: "1.9.0", wantDownload: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			if tt.version != "" {
+				writeTestInstall(t, tt.binary, tt.version)
+			}
+			if tt.missing != "" {
+				if err := os.Remove(filepath.Join(mediaMTXDir, tt.missing)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var downloaded []string
+			err := ensureMediaMTX(tt.want, func(tag string) error {
+				downloaded = append(downloaded, tag)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantDownload {
+				if len(downloaded) != 0 {
+					t.Errorf("downloaded %v, want no download", downloaded)
+				}
+				return
+			}
+			if len(downloaded) != 1 || downloaded[0] != "v1.9.0" {
+				t.Errorf("downloaded %v, want [v1.9.0]", downloaded)
+			}
+		})
+	}
+}
+
+// writeTestInstall creates a MediaMTX install in the current directory,
+// with its recorded checksum and version
+func writeTestInstall(t *testing.T, binary string, version string) {
+	t.Helper()
+
+	if err := os.Mkdir(mediaMTXDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte(binary))
+	for name, content := range map[string]string{
+		mediaMTXBinary():   binary,
+		binaryChecksumFile: hex.EncodeToString(sum[:]) + "\n",
+		versionFile:        version + "\n",
+	} {
+		if err := os.WriteFile(filepath.Join(mediaMTXDir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestInstallMediaMTX(t *testing.T) {
+	release := map[string]string{mediaMTXBinary(): "new binary", "LICENSE": "license"}
+	tarGz := tarGzArchive(t, release)
+	zipped := zipArchive(t, release)
+
+	tests := []struct {
+		name     string
+		archive  []byte
+		fileName string
+		existing bool
+		wantErr  bool
+	}{
+		{"tar.gz", tarGz, "mediamtx.tar.gz", false, false},
+		{"zip over existing install", zipped, "mediamtx.zip", true, false},
+		{"truncated tar.gz", tarGz[:len(tarGz)/2], "mediamtx.tar.gz", true, true},
+		{"truncated zip", zipped[:len(zipped)/2], "mediamtx.zip", true, true},
+		{"truncated tar.gz without install", tarGz[:len(tarGz)/2], "mediamtx.tar.gz", false, true},
+		{"missing binary", tarGzArchive(t, map[string]string{"LICENSE": "license"}), "mediamtx.tar.gz", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir(t, t.TempDir())
+			if tt.existing {
+				writeTestInstall(t, "old binary", "v1.8.0")
+				if err := os.WriteFile(filepath.Join(mediaMTXDir, configFile), []byte("# custom\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := installMediaMTX(bytes.NewReader(tt.archive), int64(len(tt.archive)), tt.fileName, "v1.9.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("installMediaMTX() = %v, want error %v", err, tt.wantErr)
+			}
+
+			// No leftovers besides the install
+			entries, err := os.ReadDir(".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.Name() != mediaMTXDir {
+					t.Errorf("left behind %s", entry.Name())
+				}
+			}
+
+			binary, binaryErr := os.ReadFile(filepath.Join(mediaMTXDir, mediaMTXBinary()))
+			switch {
+			case !tt.wantErr:
+				if string(binary) != "new binary" {
+					t.Errorf("binary = %q, want the new one", binary)
+				}
+				if version := installedMediaMTXVersion(); version != "v1.9.0" {
+					t.Errorf("installed version = %q, want v1.9.0", version)
+				}
+				if err := verifyMediaMTX(); err != nil {
+					t.Error(err)
+				}
+			case tt.existing:
+				if string(binary) != "old binary" {
+					t.Errorf("binary = %q, want the existing install kept", binary)
+				}
+				if version := installedMediaMTXVersion(); version != "v1.8.0" {
+					t.Errorf("installed version = %q, want v1.8.0 kept", version)
+				}
+			default:
+				if !os.IsNotExist(binaryErr) {
+					t.Errorf("%s exists after a failed install", mediaMTXDir)
+				}
+			}
+
+			if tt.existing {
+				content, err := os.ReadFile(filepath.Join(mediaMTXDir, configFile))
+				if err != nil || string(content) != "# custom\n" {
+					t.Errorf("config = %q (%v), want the existing one kept", content, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchChecksum(t *testing.T) {
+	const hash = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		name      string
+		checksums string
+		status    int
+		want      string
+	}{
+		{"plain", "ffff  other.tar.gz\n" + hash + "  mediamtx.tar.gz\n", http.StatusOK, hash},
+		{"binary mode", hash + " *mediamtx.tar.gz\n", http.StatusOK, hash},
+		{"upper case", strings.ToUpper(hash) + "  mediamtx.tar.gz\n", http.StatusOK, hash},
+		{"missing entry", hash + "  other.tar.gz\n", http.StatusOK, ""},
+		{"not found", "", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path != "/v1.9.0/"+releaseChecksumsFile {
+					http.NotFound(w, req)
+					return
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.checksums)
+			}))
+			defer server.Close()
+
+			got, err := fetchChecksum(server.URL + "/v1.9.0/mediamtx.tar.gz")
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("fetchChecksum() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("fetchChecksum() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadMediaMTXChecksum(t *testing.T) {
+	suffix, err := generateSuffixUrl(runtime.GOOS, runtime.GOARCH, goarm())
+	if err != nil {
+		t.Skip(err)
+	}
+	packageName := "mediamtx_v1.9.0_" + suffix
+
+	release := map[string]string{mediaMTXBinary(): "new binary"}
+	archive := tarGzArchive(t, release)
+	if strings.HasSuffix(suffix, ".zip") {
+		archive = zipArchive(t, release)
+	}
+	sum := sha256.Sum256(archive)
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  bool
+	}{
+		{"matching checksum", hex.EncodeToString(sum[:]), false},
+		{"wrong checksum", strings.Repeat("0", 64), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			releasePath := "/bluenviron/mediamtx/releases/download/v1.9.0/"
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				switch req.URL.Path {
+				case releasePath + packageName:
+					w.Write(archive)
+				case releasePath + releaseChecksumsFile:
+					io.WriteString(w, tt.checksum+"  "+packageName+"\n")
+				default:
+					http.NotFound(w, req)
+				}
+			}))
+			defer server.Close()
+
+			defer func(u string) { githubUrl = u }(githubUrl)
+			githubUrl = server.URL
+			chdir(t, t.TempDir())
+
+			err := downloadMediaMTX("v1.9.0")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("downloadMediaMTX() = %v, want error %v", err, tt.wantErr)
+			}
+
+			entries, err := os.ReadDir(".")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if len(entries) != 0 {
+					t.Errorf("left behind %v after a checksum mismatch", entries)
+				}
+				return
+			}
+			if !mediaMTXInstalled() || installedMediaMTXVersion() != "v1.9.0" {
+				t.Error("MediaMTX v1.9.0 not installed")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+// Reference: https://datatracker.ietf.org/doc/html/draft-uberti-behave-turn-rest-00
+// Reference: https://github.com/bluenviron/mediamtx/blob/main/mediamtx.yml (webrtcICEServers2)
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TURNCredentials struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username"`
+	Credential string   `json:"credential"`
+}
+
+// turnCredentials generates credentials valid for ttl, for a TURN server
+// sharing secret (coturn use-auth-secret)
+func turnCredentials(urls []string, secret string, ttl time.Duration) TURNCredentials {
+	// Username is the expiry time, credential its HMAC-SHA1
+	username := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10) + ":camcast"
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(username))
+
+	return TURNCredentials{
+		URLs:       urls,
+		Username:   username,
+		Credential: base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+	}
+}
+
+// turnHandler serves fresh TURN credentials as JSON to same-origin and allowed origins,
+// for WebRTC clients other than MediaMTX's own pages, e.g. a custom page on an allowed origin
+func turnHandler(urls []string, secret string, ttl time.Duration, allowedOrigins []string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		setCORSHeaders(w.Header(), req.Header.Get("Origin"), allowedOrigins)
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(turnCredentials(urls, secret, ttl))
+	}
+}
+
+// turnEnv returns MediaMTX settings adding the TURN servers at urls.
+// MediaMTX's own pages get their ICE servers from MediaMTX, which derives per-session
+// credentials from secret itself, so the secret never reaches the browser.
+func turnEnv(urls []string, secret string) []string {
+	var env []string
+	for i, url := range urls {
+		prefix := "MTX_WEBRTCICESERVERS2_" + strconv.Itoa(i) + "_"
+		env = append(env,
+			prefix+"URL="+url,
+			prefix+"USERNAME=AUTH_SECRET",
+			prefix+"PASSWORD="+secret,
+		)
+	}
+	return env
+}
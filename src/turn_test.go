@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTurnEnv(t *testing.T) {
+	urls := []string{"turn:turn.example.com:3478", "turns:turn.example.com:5349?transport=tcp"}
+	want := []string{
+		"MTX_WEBRTCICESERVERS2_0_URL=turn:turn.example.com:3478",
+		"MTX_WEBRTCICESERVERS2_0_USERNAME=AUTH_SECRET",
+		"MTX_WEBRTCICESERVERS2_0_PASSWORD=secret",
+		"MTX_WEBRTCICESERVERS2_1_URL=turns:turn.example.com:5349?transport=tcp",
+		"MTX_WEBRTCICESERVERS2_1_USERNAME=AUTH_SECRET",
+		"MTX_WEBRTCICESERVERS2_1_PASSWORD=secret",
+	}
+
+	if got := turnEnv(urls, "secret"); !reflect.DeepEqual(got, want) {
+		t.Errorf("turnEnv() = %v, want %v", got, want)
+	}
+	if got := turnEnv(nil, "secret"); len(got) != 0 {
+		t.Errorf("turnEnv(nil) = %v, want none", got)
+	}
+}
+
+func TestTurnHandler(t *testing.T) {
+	urls := []string{"turn:turn.example.com:3478"}
+	handler := turnHandler(urls, "secret", time.Hour, nil)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/turn", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var creds TURNCredentials
+	if err := json.NewDecoder(rec.Body).Decode(&creds); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(creds.URLs, urls) {
+		t.Errorf("urls = %v, want %v", creds.URLs, urls)
+	}
+
+	// Username is "<expiry>:camcast", credential its HMAC-SHA1
+	expiry, _, _ := strings.Cut(creds.Username, ":")
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid username %q", creds.Username)
+	}
+	if until := time.Until(time.Unix(unix, 0)); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("credentials expire in %v, want 1h", until)
+	}
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(creds.Username))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); creds.Credential != want {
+		t.Errorf("credential = %q, want %q", creds.Credential, want)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/turn", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}